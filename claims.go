@@ -0,0 +1,99 @@
+package fireauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// maxUIDLength is the longest uid CreateCustomToken accepts, matching the
+// Firebase Admin SDK's limit.
+const maxUIDLength = 128
+
+// maxClaimsBytes is the largest serialized custom-claims payload
+// CreateCustomToken accepts, matching the Firebase Admin SDK's limit.
+const maxClaimsBytes = 1000
+
+// ReservedClaims lists the claim keys CreateCustomToken rejects because they
+// are already used by the JWT spec or by Firebase itself.
+var ReservedClaims = []string{
+	"acr", "amr", "at_hash", "aud", "auth_time", "azp", "cnf", "c_hash",
+	"exp", "firebase", "iat", "iss", "jti", "nbf", "nonce", "sub", "uid",
+}
+
+// Errors returned by CreateCustomToken.
+var (
+	ErrReservedClaim  = errors.New("fireauth: claims payload uses a reserved claim key")
+	ErrClaimsTooLarge = errors.New("fireauth: claims payload must not be larger than 1000 bytes")
+	ErrInvalidUID     = errors.New("fireauth: uid must be non-empty and no longer than 128 characters")
+)
+
+// CreateCustomToken creates a token for uid with the given custom claims,
+// mirroring the Firebase Admin SDK's createCustomToken. claims must not use
+// any of the ReservedClaims keys and must serialize to no more than 1000
+// bytes; the resulting token carries them under a top-level "claims" field.
+func (t *Generator) CreateCustomToken(uid string, claims map[string]interface{}) (string, error) {
+	if uid == "" || len(uid) > maxUIDLength {
+		return "", ErrInvalidUID
+	}
+
+	for _, reserved := range ReservedClaims {
+		if _, ok := claims[reserved]; ok {
+			return "", ErrReservedClaim
+		}
+	}
+
+	if len(claims) > 0 {
+		claimBytes, err := json.Marshal(claims)
+		if err != nil {
+			return "", err
+		}
+		if len(claimBytes) > maxClaimsBytes {
+			return "", ErrClaimsTooLarge
+		}
+	}
+
+	if t.signer != nil {
+		return t.createSignedToken(uid, Data(claims), nil)
+	}
+	return t.createLegacyClaimsToken(uid, Data(claims))
+}
+
+// legacyClaim is the claim body CreateCustomToken uses for a Generator
+// created via New: the same uid/claims shape chunk0-2 introduced for
+// signer-based tokens, signed with the legacy HMAC secret instead.
+type legacyClaim struct {
+	Version  int    `json:"v"`
+	UID      string `json:"uid"`
+	Claims   Data   `json:"claims,omitempty"`
+	IssuedAt int64  `json:"iat"`
+}
+
+func (t *Generator) createLegacyClaimsToken(uid string, claims Data) (string, error) {
+	encodedHeader, err := encodedHeader()
+	if err != nil {
+		return "", err
+	}
+
+	claim := legacyClaim{
+		Version:  Version,
+		UID:      uid,
+		Claims:   claims,
+		IssuedAt: t.clock.Now().Unix(),
+	}
+
+	claimBytes, err := json.Marshal(claim)
+	if err != nil {
+		return "", err
+	}
+	encodedClaim := encode(claimBytes)
+
+	secureString := fmt.Sprintf("%s%s%s", encodedHeader, TokenSep, encodedClaim)
+	signature := sign(secureString, t.secret)
+	token := fmt.Sprintf("%s%s%s", secureString, TokenSep, signature)
+
+	if len(token) > 1024 {
+		return "", ErrTokenTooLong
+	}
+	return token, nil
+}
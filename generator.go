@@ -18,6 +18,11 @@ const (
 	TokenSep = "."
 )
 
+// defaultTokenExpiration is how long a token is valid for when Option.Expiration
+// is not set, as documented on Option.Expiration. It is measured from the
+// Generator's Clock.
+const defaultTokenExpiration = 24 * time.Hour
+
 // Generic errors
 var (
 	ErrNoUIDKey           = errors.New(`Data payload must contain a "uid" key`)
@@ -29,7 +34,10 @@ var (
 
 // Generator represents a token generator
 type Generator struct {
-	secret string
+	secret    string
+	signer    Signer
+	clock     Clock
+	clockSkew time.Duration
 }
 
 // Option represent the claims used when creating an authentication token
@@ -59,11 +67,17 @@ type Data map[string]interface{}
 // New creates a new Generator
 func New(secret string) *Generator {
 	return &Generator{
-		secret: secret,
+		secret:    secret,
+		clock:     systemClock{},
+		clockSkew: defaultClockSkew,
 	}
 }
 
-// CreateToken generates a new token with the given Data and options
+// CreateToken generates a new token with the given Data and options. If the
+// Generator was created with NewWithSigner, the token is produced in the
+// current Firebase custom-token format (RS256 or HS256 via Signer, with an
+// aud claim, iss/sub set to the service-account email when the Signer has
+// one, and exp capped at one hour); otherwise the legacy HS256 format is used.
 func (t *Generator) CreateToken(data Data, options *Option) (string, error) {
 	// make sure we have valid parameters
 	if data == nil && (options == nil || (!options.Admin && !options.Debug)) {
@@ -75,6 +89,17 @@ func (t *Generator) CreateToken(data Data, options *Option) (string, error) {
 		return "", err
 	}
 
+	if t.signer != nil {
+		uid, _ := data["uid"].(string)
+		claims := make(Data, len(data))
+		for k, v := range data {
+			if k != "uid" {
+				claims[k] = v
+			}
+		}
+		return t.createSignedToken(uid, claims, options)
+	}
+
 	// generate the encoded headers
 	encodedHeader, err := encodedHeader()
 	if err != nil {
@@ -82,16 +107,24 @@ func (t *Generator) CreateToken(data Data, options *Option) (string, error) {
 	}
 
 	// setup the claims for the token
+	now := t.clock.Now()
+	expiration := now.Add(defaultTokenExpiration).Unix()
+	if options != nil && options.Expiration != 0 {
+		expiration = options.Expiration
+	}
+
 	claim := struct {
 		*Option
-		Version  int   `json:"v"`
-		Data     Data  `json:"d"`
-		IssuedAt int64 `json:"iat"`
+		Version    int   `json:"v"`
+		Data       Data  `json:"d"`
+		IssuedAt   int64 `json:"iat"`
+		Expiration int64 `json:"exp"`
 	}{
-		Option:   options,
-		Version:  Version,
-		Data:     data,
-		IssuedAt: time.Now().Unix(),
+		Option:     options,
+		Version:    Version,
+		Data:       data,
+		IssuedAt:   now.Unix(),
+		Expiration: expiration,
 	}
 
 	// generate the encoded claims
@@ -149,5 +182,7 @@ func encode(data []byte) string {
 }
 
 func sign(message, secret string) string {
-	return encode(hmac.New(sha256.New, []byte(secret)).Sum([]byte(message)))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return encode(mac.Sum(nil))
 }
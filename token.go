@@ -0,0 +1,124 @@
+package fireauth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Token errors returned by ParseToken and VerifyToken
+var (
+	ErrTokenMalformed         = errors.New("fireauth: token is malformed")
+	ErrTokenSignatureMismatch = errors.New("fireauth: token signature does not match")
+	ErrTokenExpired           = errors.New("fireauth: token has expired")
+	ErrTokenNotYetValid       = errors.New("fireauth: token is not valid yet")
+)
+
+// defaultClockSkew is the tolerance applied to nbf/exp checks in VerifyToken
+// to account for clock drift between the server that issued a token and the
+// one verifying it.
+const defaultClockSkew = 5 * time.Minute
+
+// Clock supplies the current time to a Generator. The default is the system
+// wall clock; it can be swapped out to generate or verify tokens against a
+// fixed logical time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the default Clock, backed by the wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ParsedToken is the decoded form of a legacy-format token produced by
+// CreateToken on a Generator created with New.
+type ParsedToken struct {
+	*Option
+	Version  int   `json:"v"`
+	Data     Data  `json:"d"`
+	IssuedAt int64 `json:"iat"`
+}
+
+// ParseToken decodes token and checks that its signature was produced with
+// the Generator's secret. It does not check the nbf/exp claims; use
+// VerifyToken for that. The signature is always checked as HMAC-SHA256 with
+// the secret, so tokens produced via a Signer (NewWithSigner) are rejected
+// with ErrTokenSignatureMismatch.
+func (t *Generator) ParseToken(token string) (*ParsedToken, error) {
+	parts := strings.Split(token, TokenSep)
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	secureString := fmt.Sprintf("%s%s%s", parts[0], TokenSep, parts[1])
+	expectedSignature := sign(secureString, t.secret)
+	if subtle.ConstantTimeCompare([]byte(expectedSignature), []byte(parts[2])) != 1 {
+		return nil, ErrTokenSignatureMismatch
+	}
+
+	claimBytes, err := decode(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	parsed := &ParsedToken{}
+	if err := json.Unmarshal(claimBytes, parsed); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	return parsed, nil
+}
+
+// VerifyToken parses token and additionally checks its nbf/exp claims
+// against the Generator's Clock, allowing for the configured clock skew.
+func (t *Generator) VerifyToken(token string) (*ParsedToken, error) {
+	parsed, err := t.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.clock.Now()
+
+	if parsed.Option != nil && parsed.NotBefore != 0 {
+		if now.Add(t.clockSkew).Before(time.Unix(parsed.NotBefore, 0)) {
+			return nil, ErrTokenNotYetValid
+		}
+	}
+
+	if parsed.Option != nil && parsed.Expiration != 0 {
+		if now.Add(-t.clockSkew).After(time.Unix(parsed.Expiration, 0)) {
+			return nil, ErrTokenExpired
+		}
+	}
+
+	return parsed, nil
+}
+
+// SetClockSkew sets the tolerance window VerifyToken applies to nbf/exp
+// checks, to account for clock drift between servers. The default is five
+// minutes.
+func (t *Generator) SetClockSkew(d time.Duration) {
+	t.clockSkew = d
+}
+
+// WithClock sets the Clock the Generator consults for the "iat" claim and
+// default "exp" in CreateToken and for the nbf/exp checks in VerifyToken,
+// and returns the Generator for chaining. The default is the system wall
+// clock.
+func (t *Generator) WithClock(c Clock) *Generator {
+	t.clock = c
+	return t
+}
+
+func decode(s string) ([]byte, error) {
+	if pad := len(s) % 4; pad != 0 {
+		s += strings.Repeat("=", 4-pad)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}